@@ -0,0 +1,50 @@
+package logrusmiddleware
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestAddLogFieldGetLoggerRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+	logger.Formatter = &logrus.JSONFormatter{}
+
+	ctx := newLogFieldsContext(context.TODO(), logger)
+
+	AddLogField(ctx, "user_id", "42")
+	GetLogger(ctx).Info("did a thing")
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"user_id":"42"`)) {
+		t.Fatalf("log output %q does not contain field set via AddLogField", buf.String())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"did a thing"`)) {
+		t.Fatalf("log output %q does not contain the message", buf.String())
+	}
+}
+
+func TestGetLoggerUsesConfiguredLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+
+	ctx := newLogFieldsContext(context.TODO(), logger)
+	GetLogger(ctx).Info("routed")
+
+	if buf.Len() == 0 {
+		t.Fatal("GetLogger did not log through the configured Middleware.Logger")
+	}
+}
+
+func TestGetLoggerWithoutRequestContext(t *testing.T) {
+	// GetLogger must not panic when ctx wasn't derived from a request
+	// handled by this middleware.
+	entry := GetLogger(context.TODO())
+	if entry == nil {
+		t.Fatal("GetLogger returned nil")
+	}
+}
@@ -0,0 +1,15 @@
+package logrusmiddleware
+
+import "time"
+
+// Metrics is notified once a request has completed, after the logging
+// fields have been assembled. It turns the middleware from a pure logger
+// into a unified observability layer without requiring a second wrapper.
+//
+// The package does not ship a concrete implementation itself so that
+// importing logrusmiddleware does not pull in a metrics backend's
+// dependencies; see the prometheusmetrics subpackage for a
+// prometheus.Collector-based one.
+type Metrics interface {
+	Observe(name, component, method string, status int, size int, latency time.Duration)
+}
@@ -4,10 +4,12 @@ package logrusmiddleware
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"net"
 	"net/http"
-	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -20,6 +22,32 @@ type (
 		Logger *logrus.Logger
 		// Name is the name of the application as recorded in latency metrics
 		Name string
+		// Recover, if true, recovers panics raised by the downstream handler,
+		// writes a 500 response if nothing has been written yet, and logs the
+		// panic value and a stack trace instead of crashing the process.
+		Recover bool
+		// RequestTimeout, if set, replaces the request context with one that
+		// is cancelled after the given duration, unless the connection has
+		// been hijacked (see Handler.Hijack) in the meantime.
+		RequestTimeout time.Duration
+		// Redactor controls which query parameters, headers and form fields
+		// are masked before a request is logged. If nil, the "password" and
+		// "pw" query parameters are masked, matching historical behavior.
+		Redactor *Redactor
+		// Policy controls whether a request is logged, at what level, and
+		// whether the "starting request" line is emitted. If nil, every
+		// request is logged at Info, matching historical behavior.
+		Policy Policy
+		// Metrics, if set, is notified with each request's outcome once it
+		// completes, in addition to the usual logging.
+		Metrics Metrics
+		// RequestIDHeaders lists the headers checked, in order, for an
+		// incoming correlation ID. If unset, "X-Request-Id" is used.
+		RequestIDHeaders []string
+		// IDGenerator generates a correlation ID when none of
+		// RequestIDHeaders is present on the incoming request. If nil, a
+		// UUID is generated.
+		IDGenerator func() string
 	}
 
 	responseData struct {
@@ -34,6 +62,12 @@ type (
 		handler      http.Handler
 		component    string
 		responseData *responseData
+		nodeadline   chan struct{}
+		hijackOnce   sync.Once
+		// aborted is set by recoverPanic when the downstream handler panics
+		// with http.ErrAbortHandler, so the completion-log/metrics defer
+		// below can skip itself during the same panic unwind.
+		aborted bool
 	}
 )
 
@@ -54,12 +88,18 @@ func (m *Middleware) Handler(h http.Handler, component string) *Handler {
 }
 
 // Hijack implements http.Hijacker. It simply wraps the underlying
-// ResponseWriter's Hijack method if there is one, or returns an error.
+// ResponseWriter's Hijack method if there is one, or returns an error. Once a
+// connection is hijacked, any pending RequestTimeout is disarmed so that
+// long-lived hijacked connections (websockets, SSE) are not cancelled.
 func (h *Handler) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	if hj, ok := h.ResponseWriter.(http.Hijacker); ok {
-		return hj.Hijack()
+	hj, ok := h.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("Parent ResponseWriter is no Hijacker")
 	}
-	return nil, nil, errors.New("Parent ResponseWriter is no Hijacker")
+	if h.nodeadline != nil {
+		h.hijackOnce.Do(func() { close(h.nodeadline) })
+	}
+	return hj.Hijack()
 }
 
 // Write is a wrapper for the "real" ResponseWriter.Write
@@ -92,32 +132,14 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	h.ResponseWriter = rw
 	h.responseData = h.newResponseData()
 
-	safeURI := ""
-	uri, err := url.ParseRequestURI(r.RequestURI)
-	if err != nil {
-		safeURI = ""
-	} else {
-		query := uri.Query()
-		changes := false
-		if query.Get("password") != "" {
-			query.Set("password", "****")
-			changes = true
-		}
-		if query.Get("pw") != "" {
-			query.Set("pw", "****")
-			changes = true
-		}
-		if changes == true {
-			uri.RawQuery = query.Encode()
-			safeURI = uri.String()
-		} else {
-			safeURI = r.RequestURI
-		}
+	red := h.m.Redactor
+	if red == nil {
+		red = &defaultRedactor
 	}
 
 	fields := logrus.Fields{
 		"method":     r.Method,
-		"request":    safeURI,
+		"request":    red.redactQuery(r.RequestURI),
 		"remote":     r.RemoteAddr,
 		"referer":    r.Referer(),
 		"user-agent": r.UserAgent(),
@@ -131,26 +153,88 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		fields["component"] = h.component
 	}
 
-	info := func(msg string) {
+	if headers := red.redactHeaders(r.Header); headers != nil {
+		fields["headers"] = headers
+	}
+	if form := red.redactForm(r); form != nil {
+		fields["form"] = form
+	}
+
+	id := h.m.requestID(r)
+	h.Header().Set("X-Request-Id", id)
+	fields["request_id"] = id
+	if tc, ok := parseTraceparent(r.Header.Get("traceparent")); ok {
+		fields["trace_id"] = tc.traceID
+		fields["span_id"] = tc.spanID
+	}
+
+	policy := h.m.Policy
+	shouldLog := policy == nil || policy.ShouldLog(r)
+
+	logAt := func(level logrus.Level, msg string) {
+		if !shouldLog {
+			return
+		}
 		if l := h.m.Logger; l != nil {
-			l.WithFields(fields).Info(msg)
+			l.WithFields(fields).Log(level, msg)
 		} else {
-			logrus.WithFields(fields).Info(msg)
+			logrus.WithFields(fields).Log(level, msg)
 		}
 	}
-	info("starting request")
+	if policy == nil || !policy.SkipStart(r) {
+		logAt(logrus.InfoLevel, "starting request")
+	}
 
-	h.handler.ServeHTTP(h, r)
+	r = r.WithContext(newLogFieldsContext(context.WithValue(r.Context(), requestIDKey, id), h.m.Logger))
 
-	latency := time.Since(start)
-	fields["duration"] = float64(latency.Nanoseconds()) / float64(1000)
+	var timedOut *atomic.Bool
+	if h.m.RequestTimeout > 0 {
+		h.nodeadline = make(chan struct{})
+		done := make(chan struct{})
+		defer close(done)
 
-	status := h.responseData.status
-	if status == 0 {
-		status = 200
+		var ctx context.Context
+		ctx, timedOut = h.withDeadline(r.Context(), h.m.RequestTimeout, done)
+		r = r.WithContext(ctx)
 	}
-	fields["status"] = status
-	fields["size"] = h.responseData.size
 
-	info("completed handling request")
+	defer func() {
+		if h.aborted {
+			// The handler panicked with http.ErrAbortHandler: net/http wants
+			// that to abort the connection silently, so skip the completion
+			// log line and metrics observation entirely.
+			return
+		}
+
+		latency := time.Since(start)
+		fields["duration"] = float64(latency.Nanoseconds()) / float64(1000)
+
+		status := h.responseData.status
+		if status == 0 {
+			status = 200
+		}
+		fields["status"] = status
+		fields["size"] = h.responseData.size
+
+		if timedOut != nil && timedOut.Load() {
+			fields["timeout"] = true
+		}
+
+		logFieldsFromContext(r.Context()).merge(fields)
+
+		if h.m.Metrics != nil {
+			h.m.Metrics.Observe(h.m.Name, h.component, r.Method, status, h.responseData.size, latency)
+		}
+
+		level := logrus.InfoLevel
+		if policy != nil {
+			level = policy.Level(r, status, latency)
+		}
+		logAt(level, "completed handling request")
+	}()
+	if h.m.Recover {
+		defer h.recoverPanic(fields)
+	}
+
+	h.handler.ServeHTTP(h, r)
 }
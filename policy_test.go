@@ -0,0 +1,90 @@
+package logrusmiddleware
+
+import (
+	"hash/fnv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSamplingPolicyRateZeroAndOne(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+
+	if (SamplingPolicy{Rate: 0}).ShouldLog(r) {
+		t.Fatal("Rate: 0 should never log")
+	}
+	if !(SamplingPolicy{Rate: 1}).ShouldLog(r) {
+		t.Fatal("Rate: 1 should always log")
+	}
+}
+
+func TestSamplingPolicyIsDeterministic(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+
+	p := SamplingPolicy{Rate: 0.5}
+	first := p.ShouldLog(r)
+	for i := 0; i < 10; i++ {
+		if p.ShouldLog(r) != first {
+			t.Fatal("SamplingPolicy.ShouldLog is not deterministic for the same request")
+		}
+	}
+}
+
+// TestSamplingPolicyThreshold reproduces the hash SamplingPolicy.ShouldLog
+// computes and checks ShouldLog switches from false to true exactly at that
+// hash's threshold.
+func TestSamplingPolicyThreshold(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+
+	h := fnv.New32a()
+	h.Write([]byte(r.RemoteAddr))
+	h.Write([]byte(r.URL.Path))
+	threshold := float64(h.Sum32()) / float64(1<<32)
+
+	below := threshold - 0.0001
+	above := threshold + 0.0001
+
+	if below > 0 && (SamplingPolicy{Rate: below}).ShouldLog(r) {
+		t.Fatalf("Rate just below the request's hash threshold (%v) should not log", below)
+	}
+	if above < 1 && !(SamplingPolicy{Rate: above}).ShouldLog(r) {
+		t.Fatalf("Rate just above the request's hash threshold (%v) should log", above)
+	}
+}
+
+func TestStatusLevelPolicy(t *testing.T) {
+	p := StatusLevelPolicy{}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	cases := []struct {
+		status int
+		want   logrus.Level
+	}{
+		{200, logrus.InfoLevel},
+		{404, logrus.WarnLevel},
+		{500, logrus.ErrorLevel},
+	}
+	for _, c := range cases {
+		if got := p.Level(r, c.status, 0); got != c.want {
+			t.Errorf("Level(status=%d) = %v, want %v", c.status, got, c.want)
+		}
+	}
+}
+
+func TestLatencyLevelPolicy(t *testing.T) {
+	p := LatencyLevelPolicy{Threshold: 100 * time.Millisecond}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if got := p.Level(r, 200, 50*time.Millisecond); got != logrus.InfoLevel {
+		t.Errorf("Level under threshold = %v, want Info", got)
+	}
+	if got := p.Level(r, 200, 150*time.Millisecond); got != logrus.WarnLevel {
+		t.Errorf("Level over threshold = %v, want Warn", got)
+	}
+}
@@ -0,0 +1,85 @@
+// Package prometheusmetrics provides a Prometheus-backed implementation of
+// logrusmiddleware.Metrics, kept separate from the core package so that
+// logrusmiddleware's own import graph stays free of the Prometheus client
+// and its transitive dependencies.
+package prometheusmetrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Collector is a logrusmiddleware.Metrics implementation that exposes a
+// request counter and a latency histogram labelled by name, component,
+// method and status, and implements prometheus.Collector so it can be
+// registered on any registry.
+type Collector struct {
+	requests *prometheus.CounterVec
+	size     *prometheus.HistogramVec
+	latency  *prometheus.HistogramVec
+}
+
+// New builds a Collector. buckets configures the latency histogram's
+// buckets, in seconds; if nil, prometheus.DefBuckets is used.
+func New(buckets []float64) *Collector {
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+
+	labels := []string{"name", "component", "method", "status"}
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests handled.",
+		}, labels),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "Size of HTTP responses in bytes.",
+			Buckets: prometheus.ExponentialBuckets(64, 4, 10),
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency of HTTP requests in seconds.",
+			Buckets: buckets,
+		}, labels),
+	}
+}
+
+// Observe implements logrusmiddleware.Metrics.
+func (c *Collector) Observe(name, component, method string, status int, size int, latency time.Duration) {
+	labels := prometheus.Labels{
+		"name":      name,
+		"component": component,
+		"method":    method,
+		"status":    strconv.Itoa(status),
+	}
+	c.requests.With(labels).Inc()
+	c.size.With(labels).Observe(float64(size))
+	c.latency.With(labels).Observe(latency.Seconds())
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.size.Describe(ch)
+	c.latency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.size.Collect(ch)
+	c.latency.Collect(ch)
+}
+
+// Handler returns an http.Handler serving c's metrics in the Prometheus
+// exposition format, for mounting at e.g. "/metrics".
+func (c *Collector) Handler() http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(c)
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,30 @@
+package prometheusmetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollectorObserve(t *testing.T) {
+	c := New(nil)
+
+	c.Observe("myapp", "api", "GET", 200, 1024, 42*time.Millisecond)
+
+	got := testutil.ToFloat64(c.requests.WithLabelValues("myapp", "api", "GET", "200"))
+	if got != 1 {
+		t.Fatalf("http_requests_total = %v, want 1", got)
+	}
+}
+
+func TestCollectorImplementsPrometheusCollector(t *testing.T) {
+	c := New(nil)
+	c.Observe("myapp", "api", "GET", 200, 1024, 42*time.Millisecond)
+
+	// CollectAndCount registers c on a throwaway registry and gathers from
+	// it, exercising Describe/Collect the same way promhttp.HandlerFor does.
+	if n := testutil.CollectAndCount(c); n == 0 {
+		t.Fatal("Collector.Collect produced no metrics")
+	}
+}
@@ -0,0 +1,42 @@
+package logrusmiddleware
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recoverPanic recovers a panic from inside h.handler.ServeHTTP, writes a
+// 500 response if nothing has been written yet, and logs the panic value
+// together with a stack trace using fields. It re-panics http.ErrAbortHandler
+// without logging, preserving net/http's special handling of that sentinel;
+// it also marks h.aborted so the completion log line and metrics, which run
+// in a separate deferred function during the same panic unwind, are skipped
+// too.
+func (h *Handler) recoverPanic(fields logrus.Fields) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+	if rec == http.ErrAbortHandler {
+		h.aborted = true
+		panic(rec)
+	}
+
+	if h.responseData.status == 0 {
+		// Write through h, not the raw ResponseWriter, so the error body's
+		// bytes are still accounted for in h.responseData.size.
+		http.Error(h, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+
+	fields["panic"] = true
+	buf := make([]byte, 4096)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	logger := h.m.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	logger.WithFields(fields).WithField("stack", string(buf)).Error(rec)
+}
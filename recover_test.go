@@ -0,0 +1,83 @@
+package logrusmiddleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeMetrics struct {
+	observed bool
+}
+
+func (f *fakeMetrics) Observe(name, component, method string, status int, size int, latency time.Duration) {
+	f.observed = true
+}
+
+func TestRecoverPanicLogs500AndStack(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+	logger.Formatter = &logrus.JSONFormatter{}
+
+	m := &Middleware{Logger: logger, Recover: true}
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), "")
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	h.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusInternalServerError)
+	}
+	if rw.Body.Len() == 0 {
+		t.Fatal("no response body written on panic")
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"panic":true`)) {
+		t.Fatalf("log output %q missing panic field", buf.String())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"status":500`)) {
+		t.Fatalf("log output %q missing status 500 on completion line", buf.String())
+	}
+}
+
+// TestRecoverPanicAbortHandlerSuppressesCompletion verifies that panicking
+// with http.ErrAbortHandler not only re-panics without a panic-specific log
+// entry, but also suppresses the regular "completed handling request" line
+// and metrics observation for that request.
+func TestRecoverPanicAbortHandlerSuppressesCompletion(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+	logger.Formatter = &logrus.JSONFormatter{}
+
+	metrics := &fakeMetrics{}
+	m := &Middleware{Logger: logger, Recover: true, Metrics: metrics}
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}), "")
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	defer func() {
+		rec := recover()
+		if rec != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler to propagate", rec)
+		}
+		if bytes.Contains(buf.Bytes(), []byte("completed handling request")) {
+			t.Fatalf("completion log line was emitted for an aborted connection: %q", buf.String())
+		}
+		if metrics.observed {
+			t.Fatal("metrics were observed for an aborted connection")
+		}
+	}()
+
+	h.ServeHTTP(rw, req)
+}
@@ -0,0 +1,161 @@
+package logrusmiddleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxRedactedFormSize is the largest request body that redactForm will read
+// in order to mask sensitive form fields. Larger bodies are left alone and
+// no "form" field is added to the log line.
+const maxRedactedFormSize = 1 << 20 // 1MiB
+
+// defaultRedactor preserves the middleware's historical behavior of masking
+// the "password" and "pw" query parameters when no Redactor is configured.
+var defaultRedactor = Redactor{
+	QueryParams: []string{"password", "pw"},
+}
+
+// Redactor describes which query parameters, headers and form fields should
+// be masked before a request is logged. The zero value redacts nothing; use
+// Middleware.Redactor to override the package default, which preserves the
+// historical password/pw query redaction.
+type Redactor struct {
+	// QueryParams lists query parameter names whose values are masked.
+	QueryParams []string
+	// Headers lists header names (case-insensitive) whose values are masked,
+	// e.g. "Authorization", "Cookie", "X-Api-Key".
+	Headers []string
+	// FormFields lists application/x-www-form-urlencoded field names whose
+	// values are masked. Form bodies are only inspected when set.
+	FormFields []string
+	// RedactFunc, if set, computes the logged value for a masked key/value
+	// pair, e.g. to partially reveal a value or log an HMAC fingerprint
+	// instead of a fixed placeholder. It defaults to returning "****".
+	RedactFunc func(key, value string) string
+}
+
+func (red *Redactor) mask(key, value string) string {
+	if red.RedactFunc != nil {
+		return red.RedactFunc(key, value)
+	}
+	return "****"
+}
+
+func (red *Redactor) contains(list []string, name string) bool {
+	for _, n := range list {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactQuery returns requestURI with any configured query parameters
+// masked. It falls back to the empty string if requestURI cannot be parsed,
+// matching the middleware's historical behavior.
+func (red *Redactor) redactQuery(requestURI string) string {
+	uri, err := url.ParseRequestURI(requestURI)
+	if err != nil {
+		return ""
+	}
+
+	query := uri.Query()
+	changed := false
+	for _, name := range red.QueryParams {
+		if query.Get(name) != "" {
+			query.Set(name, red.mask(name, query.Get(name)))
+			changed = true
+		}
+	}
+	if !changed {
+		return requestURI
+	}
+	uri.RawQuery = query.Encode()
+	return uri.String()
+}
+
+// redactHeaders returns a copy of header, keeping the first value of each
+// header, with the configured header values masked, or nil if no headers are
+// configured.
+func (red *Redactor) redactHeaders(header http.Header) logrus.Fields {
+	if len(red.Headers) == 0 {
+		return nil
+	}
+
+	fields := logrus.Fields{}
+	for name, values := range header {
+		if len(values) == 0 {
+			continue
+		}
+		if red.contains(red.Headers, name) {
+			fields[name] = red.mask(name, values[0])
+		} else {
+			fields[name] = values[0]
+		}
+	}
+	return fields
+}
+
+// restoreReadCloser rebuilds an io.ReadCloser that yields raw followed by
+// whatever remains unread in tail, while forwarding Close to tail so the
+// underlying connection is still released normally.
+type restoreReadCloser struct {
+	io.Reader
+	tail io.Closer
+}
+
+func (rc restoreReadCloser) Close() error { return rc.tail.Close() }
+
+// redactForm reads and replaces r.Body, returning the request's form values
+// with the configured fields masked, or nil if form redaction is not
+// configured, the content-type is not application/x-www-form-urlencoded, or
+// the body exceeds maxRedactedFormSize. r.Body is always left readable with
+// its original content afterwards, whether or not redaction happened, so
+// downstream handlers that read the body directly are unaffected.
+func (red *Redactor) redactForm(r *http.Request) logrus.Fields {
+	if len(red.FormFields) == 0 || r.Body == nil {
+		return nil
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || contentType != "application/x-www-form-urlencoded" {
+		return nil
+	}
+
+	tail := r.Body
+	raw, err := io.ReadAll(io.LimitReader(tail, maxRedactedFormSize+1))
+	if err != nil || len(raw) > maxRedactedFormSize {
+		// Either unreadable or over the cap: put the body back exactly as
+		// it was (read prefix plus whatever is still unread) and skip
+		// redaction rather than parse an uncapped body.
+		r.Body = restoreReadCloser{io.MultiReader(bytes.NewReader(raw), tail), tail}
+		return nil
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(raw))
+	parseErr := r.ParseForm()
+	r.Body = restoreReadCloser{bytes.NewReader(raw), tail}
+	if parseErr != nil {
+		return nil
+	}
+
+	fields := logrus.Fields{}
+	for name, values := range r.PostForm {
+		if len(values) == 0 {
+			continue
+		}
+		if red.contains(red.FormFields, name) {
+			fields[name] = red.mask(name, values[0])
+		} else {
+			fields[name] = values[0]
+		}
+	}
+	return fields
+}
@@ -0,0 +1,58 @@
+package logrusmiddleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRedactFormPreservesBody verifies that redacting form fields does not
+// consume the body for downstream handlers that read r.Body directly
+// instead of going through r.ParseForm/r.PostForm.
+func TestRedactFormPreservesBody(t *testing.T) {
+	red := &Redactor{FormFields: []string{"password"}}
+
+	body := "username=alice&password=hunter2"
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	fields := red.redactForm(r)
+	if fields["password"] != "****" {
+		t.Fatalf("password field = %v, want ****", fields["password"])
+	}
+
+	got, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("reading r.Body after redactForm: %v", err)
+	}
+	if string(got) != body {
+		t.Fatalf("r.Body after redactForm = %q, want %q", got, body)
+	}
+}
+
+// TestRedactFormOverCapPreservesBody verifies that a body over
+// maxRedactedFormSize is left fully intact and unredacted, even though its
+// Content-Length is unknown (as with a chunked request).
+func TestRedactFormOverCapPreservesBody(t *testing.T) {
+	red := &Redactor{FormFields: []string{"password"}}
+
+	body := "password=" + strings.Repeat("a", maxRedactedFormSize+1)
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ContentLength = -1
+
+	fields := red.redactForm(r)
+	if fields != nil {
+		t.Fatalf("fields = %v, want nil for an over-cap body", fields)
+	}
+
+	got, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("reading r.Body after redactForm: %v", err)
+	}
+	if string(got) != body {
+		t.Fatalf("r.Body after redactForm has length %d, want %d", len(got), len(body))
+	}
+}
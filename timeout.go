@@ -0,0 +1,43 @@
+package logrusmiddleware
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// withDeadline returns a context derived from parent that is cancelled after
+// timeout, unless the handler returns first (the caller closes done) or the
+// connection is hijacked first (see Handler.Hijack), in which case
+// h.nodeadline is closed. When the handler returns first, the deadline
+// goroutine exits promptly instead of lingering for the rest of timeout.
+// When the connection is hijacked first, the deadline is disarmed and ctx is
+// only cancelled once the handler returns or parent is cancelled. The
+// returned timedOut flag
+// is set, behind an atomic, to true if the deadline fired before the
+// handler returned.
+func (h *Handler) withDeadline(parent context.Context, timeout time.Duration, done <-chan struct{}) (ctx context.Context, timedOut *atomic.Bool) {
+	ctx, cancel := context.WithCancel(parent)
+	timedOut = new(atomic.Bool)
+
+	timer := time.NewTimer(timeout)
+	go func() {
+		defer cancel()
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+		case <-done:
+		case <-h.nodeadline:
+			// Hijacked: leave ctx alone until the handler returns.
+			timer.Stop()
+			select {
+			case <-ctx.Done():
+			case <-done:
+			}
+		case <-timer.C:
+			timedOut.Store(true)
+		}
+	}()
+
+	return ctx, timedOut
+}
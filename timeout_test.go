@@ -0,0 +1,37 @@
+package logrusmiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestRequestTimeoutGoroutineLeak verifies that the deadline goroutine
+// spawned for a RequestTimeout exits as soon as the handler returns, rather
+// than lingering until the timeout elapses.
+func TestRequestTimeoutGoroutineLeak(t *testing.T) {
+	m := &Middleware{RequestTimeout: time.Hour}
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), "")
+
+	before := runtime.NumGoroutine()
+
+	const requests = 50
+	for i := 0; i < requests; i++ {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		h.ServeHTTP(rw, req)
+	}
+
+	// Give any leaked goroutines a chance to show up before we count them.
+	runtime.Gosched()
+	time.Sleep(10 * time.Millisecond)
+
+	after := runtime.NumGoroutine()
+	if after > before+requests/2 {
+		t.Fatalf("goroutine count grew from %d to %d after %d requests with a 1h RequestTimeout; deadline goroutines are leaking", before, after, requests)
+	}
+}
@@ -0,0 +1,67 @@
+package logrusmiddleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// requestIDKey is the context key under which the request's correlation ID
+// is stored.
+const requestIDKey contextKey = "requestID"
+
+// defaultRequestIDHeaders is used to look up an incoming correlation ID when
+// Middleware.RequestIDHeaders is unset.
+var defaultRequestIDHeaders = []string{"X-Request-Id"}
+
+// RequestIDFromContext returns the correlation ID assigned to the request
+// ctx was derived from, or "" if ctx was not derived from a request handled
+// by this middleware.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// requestID looks up an incoming correlation ID from the configured
+// headers, generating one with m.IDGenerator (or a UUID) if none is
+// present.
+func (m *Middleware) requestID(r *http.Request) string {
+	headers := m.RequestIDHeaders
+	if len(headers) == 0 {
+		headers = defaultRequestIDHeaders
+	}
+
+	for _, header := range headers {
+		if id := r.Header.Get(header); id != "" {
+			return id
+		}
+	}
+
+	if m.IDGenerator != nil {
+		return m.IDGenerator()
+	}
+	return uuid.New().String()
+}
+
+// traceContext holds the trace_id/span_id parsed out of a W3C Trace Context
+// "traceparent" header, so completion log lines can be joined against
+// OpenTelemetry traces.
+type traceContext struct {
+	traceID string
+	spanID  string
+}
+
+// parseTraceparent parses a W3C "traceparent" header of the form
+// "version-traceid-spanid-flags". It returns ok=false if header does not have
+// four dash-separated parts with a 32-character trace ID and a 16-character
+// span ID, which includes an empty header; the characters themselves are not
+// checked to be hex digits.
+func parseTraceparent(header string) (tc traceContext, ok bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return traceContext{}, false
+	}
+	return traceContext{traceID: parts[1], spanID: parts[2]}, true
+}
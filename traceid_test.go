@@ -0,0 +1,74 @@
+package logrusmiddleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	cases := []struct {
+		name    string
+		header  string
+		wantOK  bool
+		traceID string
+		spanID  string
+	}{
+		{
+			name:    "valid",
+			header:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantOK:  true,
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			spanID:  "00f067aa0ba902b7",
+		},
+		{name: "empty", header: "", wantOK: false},
+		{name: "too few parts", header: "00-4bf92f3577b34da6a3ce929d0e0e4736", wantOK: false},
+		{name: "short trace id", header: "00-abc-00f067aa0ba902b7-01", wantOK: false},
+		{name: "short span id", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-abc-01", wantOK: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tc, ok := parseTraceparent(c.header)
+			if ok != c.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, c.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if tc.traceID != c.traceID || tc.spanID != c.spanID {
+				t.Fatalf("got trace_id=%q span_id=%q, want trace_id=%q span_id=%q", tc.traceID, tc.spanID, c.traceID, c.spanID)
+			}
+		})
+	}
+}
+
+func TestMiddlewareRequestIDUsesIncomingHeader(t *testing.T) {
+	m := &Middleware{}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Request-Id", "incoming-id")
+
+	if got := m.requestID(r); got != "incoming-id" {
+		t.Fatalf("requestID = %q, want %q", got, "incoming-id")
+	}
+}
+
+func TestMiddlewareRequestIDGeneratesWhenAbsent(t *testing.T) {
+	m := &Middleware{IDGenerator: func() string { return "generated-id" }}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if got := m.requestID(r); got != "generated-id" {
+		t.Fatalf("requestID = %q, want %q", got, "generated-id")
+	}
+}
+
+func TestRequestIDFromContextRoundTrip(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestIDKey, "abc-123")
+	if got := RequestIDFromContext(ctx); got != "abc-123" {
+		t.Fatalf("RequestIDFromContext = %q, want %q", got, "abc-123")
+	}
+	if got := RequestIDFromContext(context.Background()); got != "" {
+		t.Fatalf("RequestIDFromContext on bare context = %q, want empty", got)
+	}
+}